@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+
+	"github.com/YimiaoHao/wator-project/behavior"
+	"github.com/YimiaoHao/wator-project/policy"
+)
+
+// Sight is the search radius (in cells, measured from the agent itself)
+// that the "astar" behaviors use to find a target or threat, configured
+// via the -sight flag. The "greedy" behaviors only look at neigh4.
+var Sight = 5
+
+// SharkAI and FishAI are the selected behaviors, configured via the
+// -shark-ai / -fish-ai flags. They default to "random", which reproduces
+// the simulation's original hardcoded movement rules.
+var (
+	SharkAI behavior.AgentBehavior = behavior.NewShark("random")
+	FishAI  behavior.AgentBehavior = behavior.NewFish("random")
+)
+
+func toBP(p P) behavior.P { return behavior.P{X: p.X, Y: p.Y} }
+
+// NewSharkAI builds the shark behavior named by the -shark-ai flag (or a
+// recording's Header.SharkAI), loading trained weights from policyPath
+// when name is "policy". Shared by main.go and RunVerify so a
+// -shark-ai=policy recording can be reconstructed the same way it was
+// originally configured.
+func NewSharkAI(name, policyPath string, workers int) (behavior.AgentBehavior, error) {
+	if name != "policy" {
+		return behavior.NewShark(name), nil
+	}
+	f, err := os.Open(policyPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	net, err := policy.Load(f)
+	if err != nil {
+		return nil, err
+	}
+	return policy.NewSharkPolicy(net, workers), nil
+}
+
+// gridKindFn adapts a Cell grid into the behavior package's CellKind
+// accessor, wrapping arbitrary coordinates onto the torus so astar/greedy
+// can query beyond neigh4 without bounds-checking themselves.
+func gridKindFn(grid [][]Cell, n int) func(x, y int) behavior.CellKind {
+	return func(x, y int) behavior.CellKind {
+		x, y = wrapMod(x, n), wrapMod(y, n)
+		switch grid[y][x].Type {
+		case Fish:
+			return behavior.FishCell
+		case Shark:
+			return behavior.SharkCell
+		default:
+			return behavior.Empty
+		}
+	}
+}
+
+// scentFn adapts a scent grid the same way gridKindFn adapts Grid.
+func scentFn(grid [][]float32, n int) func(x, y int) float32 {
+	return func(x, y int) float32 {
+		return grid[wrapMod(y, n)][wrapMod(x, n)]
+	}
+}
+
+// terrainPassableFn adapts a Terrain grid into the behavior package's
+// Passable accessor: Reef is the only impassable kind. astar needs this
+// directly (unlike random/greedy/policy, it plans paths through cells
+// beyond ctx.Neighbors, so the engine's own neigh4 reef filter never
+// applies to it).
+func terrainPassableFn(terrain [][]Terrain, n int) func(x, y int) bool {
+	return func(x, y int) bool {
+		x, y = wrapMod(x, n), wrapMod(y, n)
+		return terrain[y][x] != Reef
+	}
+}
+
+// wrapMod wraps i onto [0, n), unlike wrap (world.go) which only handles
+// a single step past the edge; astar/greedy can query coordinates many
+// cells outside the grid while following a path.
+func wrapMod(i, n int) int { return ((i % n) + n) % n }
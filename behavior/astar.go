@@ -0,0 +1,85 @@
+package behavior
+
+// astarShark hunts the nearest fish within Sight by running A* to it and
+// following only the first step of the plan (re-planned every chronon,
+// since the target keeps moving). Falls back to randomShark when no fish
+// is in range or no path exists.
+type astarShark struct {
+	fallback AgentBehavior
+}
+
+func newAstarShark() AgentBehavior { return &astarShark{fallback: newRandomShark()} }
+
+func (b *astarShark) Plan(ctx BehaviorCtx) Action {
+	target, ok := NearestMatch(ctx.Size, ctx.Sight, ctx.Self, func(p P) bool {
+		return ctx.Grid(p.X, p.Y) == FishCell
+	})
+	if !ok {
+		return b.fallback.Plan(ctx)
+	}
+	passable := func(p P) bool { return ctx.Grid(p.X, p.Y) == Empty && ctx.Passable(p.X, p.Y) }
+	path := FindPath(ctx.Size, ctx.Self, target, passable)
+	if len(path) < 2 {
+		return b.fallback.Plan(ctx)
+	}
+	if path[1] == target {
+		return Action{Kind: Eat, To: target}
+	}
+	return Action{Kind: Move, To: path[1]}
+}
+
+func (b *astarShark) OnStep(ctx BehaviorCtx) {}
+
+// astarFish plans an escape path away from the nearest shark within
+// Sight: it picks the empty cell near it that maximizes distance from
+// the threat (see farthestFrom), then follows the first step of the A*
+// path to it.
+// Falls back to randomFish when no shark is in range or no path exists.
+type astarFish struct {
+	fallback AgentBehavior
+}
+
+func newAstarFish() AgentBehavior { return &astarFish{fallback: newRandomFish()} }
+
+func (b *astarFish) Plan(ctx BehaviorCtx) Action {
+	threat, ok := NearestMatch(ctx.Size, ctx.Sight, ctx.Self, func(p P) bool {
+		return ctx.Grid(p.X, p.Y) == SharkCell
+	})
+	if !ok {
+		return b.fallback.Plan(ctx)
+	}
+	refuge, ok := farthestFrom(ctx, threat)
+	if !ok {
+		return b.fallback.Plan(ctx)
+	}
+	passable := func(p P) bool { return ctx.Grid(p.X, p.Y) == Empty && ctx.Passable(p.X, p.Y) }
+	path := FindPath(ctx.Size, ctx.Self, refuge, passable)
+	if len(path) < 2 {
+		return b.fallback.Plan(ctx)
+	}
+	return Action{Kind: Move, To: path[1]}
+}
+
+func (b *astarFish) OnStep(ctx BehaviorCtx) {}
+
+// farthestFrom returns the empty, passable cell in the square of side
+// 2*Sight+1 centered on ctx.Self that is farthest (by torusDist) from
+// threat. Whether the cell can be reached from ctx.Self is not checked.
+func farthestFrom(ctx BehaviorCtx, threat P) (P, bool) {
+	var best P
+	bestD := -1
+	found := false
+	r := ctx.Sight
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			p := P{X: mod(ctx.Self.X+dx, ctx.Size), Y: mod(ctx.Self.Y+dy, ctx.Size)}
+			if ctx.Grid(p.X, p.Y) != Empty || !ctx.Passable(p.X, p.Y) {
+				continue
+			}
+			if d := torusDist(p.X-threat.X, p.Y-threat.Y, ctx.Size); d > bestD {
+				bestD, best, found = d, p, true
+			}
+		}
+	}
+	return best, found
+}
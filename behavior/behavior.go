@@ -0,0 +1,117 @@
+// Package behavior defines the pluggable agent decision-making used by
+// the simulation's fish and sharks. It has no dependency on package
+// main: the engine builds a BehaviorCtx each chronon from its own World
+// state and hands it to whichever AgentBehavior was selected via the
+// -shark-ai / -fish-ai flags.
+package behavior
+
+// ActionKind is the kind of action an AgentBehavior requests for a
+// single chronon.
+type ActionKind int
+
+const (
+	Stay  ActionKind = iota ///< Remain in the current cell.
+	Move                    ///< Move into an empty neighboring cell.
+	Eat                     ///< Move into a cell occupied by prey, consuming it.
+	Breed                   ///< Reserved for behaviors that want to veto/force breeding explicitly.
+)
+
+// Action is the result of a Plan call.
+type Action struct {
+	Kind ActionKind
+	To   P ///< Destination cell for Move/Eat; ignored for Stay/Breed.
+}
+
+// P is a torus grid coordinate. It mirrors main.P so this package stays
+// free of a dependency on package main.
+type P struct {
+	X int
+	Y int
+}
+
+// CellKind mirrors main.CellType for the same reason.
+type CellKind int
+
+const (
+	Empty     CellKind = iota ///< Unoccupied cell.
+	FishCell                  ///< Cell occupied by a fish.
+	SharkCell                 ///< Cell occupied by a shark.
+)
+
+// Neighbor is one candidate cell an agent may act on this chronon. The
+// engine only includes neighbors that are legal targets under its own
+// collision rules (e.g. already claimed cells in the next-state buffer
+// are omitted), so an AgentBehavior never has to reason about buffering.
+type Neighbor struct {
+	Pos  P
+	Kind CellKind
+}
+
+// BehaviorCtx is the read-only view of the world an AgentBehavior gets
+// when planning for a single agent.
+type BehaviorCtx struct {
+	Self         P
+	Size         int        ///< Grid side length, needed for toroidal distance math.
+	Neighbors    []Neighbor ///< Legal neigh4 candidates this chronon (see Neighbor).
+	Energy       int        ///< Shark energy; zero for fish.
+	BreedTimer   int
+	BreedSteps   int
+	Sight        int  ///< Search radius (in cells, from Self) of the astar behaviors; greedy ignores it.
+	ScentEnabled bool ///< Mirrors the -scent flag; scent closures read as all-zero when false.
+
+	// Grid reports the occupant kind at an arbitrary (possibly
+	// out-of-range) coordinate, wrapping around the torus itself.
+	Grid func(x, y int) CellKind
+	// Passable reports whether terrain allows an agent onto (x, y) at
+	// all (false only for Reef). Grid alone can't express this: a Reef
+	// cell has no occupant, so it reads as Empty there too. astar needs
+	// this directly to keep its planned paths off reef, since it is not
+	// restricted to the engine's pre-filtered Neighbors list the way
+	// random/greedy/policy are.
+	Passable func(x, y int) bool
+	// FishScent/SharkScent sample the scent trails at an arbitrary
+	// coordinate, wrapping around the torus itself.
+	FishScent  func(x, y int) float32
+	SharkScent func(x, y int) float32
+
+	// RandFloat32/RandIntn are the step's random source. StepPar passes
+	// its per-segment *rand.Rand, so each worker draws from its own
+	// seeded source; StepSeq passes the package-level math/rand
+	// functions.
+	RandFloat32 func() float32
+	RandIntn    func(n int) int
+}
+
+// AgentBehavior selects an action for one agent each chronon.
+// Implementations must be safe to call concurrently from different
+// StepPar workers: Plan/OnStep for one agent never touch another
+// agent's state directly, only through the BehaviorCtx they're given.
+type AgentBehavior interface {
+	// Plan returns the action to take this chronon given ctx.
+	Plan(ctx BehaviorCtx) Action
+	// OnStep is called once per agent per chronon after its move has
+	// been resolved, so stateful behaviors (e.g. a trained policy) can
+	// update internal state such as an eligibility trace.
+	OnStep(ctx BehaviorCtx)
+}
+
+// weightedChoice picks an index into weights proportional to its value,
+// using f as the source of randomness. If every weight is zero it falls
+// back to a uniform random tie-break.
+func weightedChoice(f func() float32, weights []float32) int {
+	var total float32
+	for _, wt := range weights {
+		total += wt
+	}
+	if total <= 0 {
+		return int(f() * float32(len(weights)))
+	}
+	r := f() * total
+	for i, wt := range weights {
+		r -= wt
+		if r <= 0 {
+			return i
+		}
+	}
+	return len(weights) - 1
+}
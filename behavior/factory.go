@@ -0,0 +1,26 @@
+package behavior
+
+// NewShark constructs a shark AgentBehavior by name ("random", "greedy",
+// or "astar"), defaulting to "random" for any other value.
+func NewShark(name string) AgentBehavior {
+	switch name {
+	case "greedy":
+		return newGreedyShark()
+	case "astar":
+		return newAstarShark()
+	default:
+		return newRandomShark()
+	}
+}
+
+// NewFish constructs a fish AgentBehavior by name; see NewShark.
+func NewFish(name string) AgentBehavior {
+	switch name {
+	case "greedy":
+		return newGreedyFish()
+	case "astar":
+		return newAstarFish()
+	default:
+		return newRandomFish()
+	}
+}
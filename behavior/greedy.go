@@ -0,0 +1,68 @@
+package behavior
+
+// greedyShark eats a random adjacent fish if any; otherwise it moves to
+// the empty neighbor with the strongest FishScent, chasing the best trail
+// instead of picking at random; ties fall back to a random pick among the
+// tied candidates.
+type greedyShark struct{}
+
+func newGreedyShark() AgentBehavior { return greedyShark{} }
+
+func (greedyShark) Plan(ctx BehaviorCtx) Action {
+	var fish, empty []Neighbor
+	for _, nb := range ctx.Neighbors {
+		switch nb.Kind {
+		case FishCell:
+			fish = append(fish, nb)
+		case Empty:
+			empty = append(empty, nb)
+		}
+	}
+	if len(fish) > 0 {
+		return Action{Kind: Eat, To: fish[ctx.RandIntn(len(fish))].Pos}
+	}
+	if len(empty) == 0 {
+		return Action{Kind: Stay}
+	}
+	return Action{Kind: Move, To: bestNeighbor(ctx, empty, ctx.FishScent)}
+}
+
+func (greedyShark) OnStep(ctx BehaviorCtx) {}
+
+// greedyFish moves to the empty neighbor with the weakest SharkScent.
+type greedyFish struct{}
+
+func newGreedyFish() AgentBehavior { return greedyFish{} }
+
+func (greedyFish) Plan(ctx BehaviorCtx) Action {
+	var empty []Neighbor
+	for _, nb := range ctx.Neighbors {
+		if nb.Kind == Empty {
+			empty = append(empty, nb)
+		}
+	}
+	if len(empty) == 0 {
+		return Action{Kind: Stay}
+	}
+	flee := func(x, y int) float32 { return -ctx.SharkScent(x, y) }
+	return Action{Kind: Move, To: bestNeighbor(ctx, empty, flee)}
+}
+
+func (greedyFish) OnStep(ctx BehaviorCtx) {}
+
+// bestNeighbor returns the candidate maximizing score, breaking ties
+// uniformly at random among the candidates sharing the best score.
+func bestNeighbor(ctx BehaviorCtx, cands []Neighbor, score func(x, y int) float32) P {
+	best := cands[:1]
+	bestScore := score(cands[0].Pos.X, cands[0].Pos.Y)
+	for _, nb := range cands[1:] {
+		s := score(nb.Pos.X, nb.Pos.Y)
+		switch {
+		case s > bestScore:
+			bestScore, best = s, []Neighbor{nb}
+		case s == bestScore:
+			best = append(best, nb)
+		}
+	}
+	return best[ctx.RandIntn(len(best))].Pos
+}
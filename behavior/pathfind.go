@@ -0,0 +1,144 @@
+package behavior
+
+import "container/heap"
+
+// torusDist is the toroidal Manhattan distance between two points n
+// cells apart on each axis, corrected for wraparound:
+// min(|dx|, n-|dx|) + min(|dy|, n-|dy|).
+func torusDist(dx, dy, n int) int {
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	wx, wy := dx, dy
+	if n-dx < wx {
+		wx = n - dx
+	}
+	if n-dy < wy {
+		wy = n - dy
+	}
+	return wx + wy
+}
+
+func heuristic(a, b P, n int) int { return torusDist(b.X-a.X, b.Y-a.Y, n) }
+
+func mod(i, n int) int { return ((i % n) + n) % n }
+
+func torusNeighbors(p P, n int) [4]P {
+	return [4]P{
+		{p.X, mod(p.Y-1, n)},
+		{mod(p.X+1, n), p.Y},
+		{p.X, mod(p.Y+1, n)},
+		{mod(p.X-1, n), p.Y},
+	}
+}
+
+func idx(p P, n int) int { return p.Y*n + p.X }
+
+// NearestMatch scans the cells within radius of start (by torusDist) for
+// the closest one accepted by match, returning false if none qualify.
+func NearestMatch(n, radius int, start P, match func(p P) bool) (P, bool) {
+	var best P
+	bestD := radius + 1
+	found := false
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			if dx == 0 && dy == 0 {
+				continue
+			}
+			d := torusDist(dx, dy, n)
+			if d >= bestD {
+				continue
+			}
+			p := P{X: mod(start.X+dx, n), Y: mod(start.Y+dy, n)}
+			if match(p) {
+				best, bestD, found = p, d, true
+			}
+		}
+	}
+	return best, found
+}
+
+type openItem struct {
+	pos  P
+	g, h int
+}
+
+// openHeap is a container/heap min-heap ordered by f = g + h, tie-broken
+// toward the lower heuristic (closer to the target).
+type openHeap []openItem
+
+func (h openHeap) Len() int { return len(h) }
+func (h openHeap) Less(i, j int) bool {
+	fi, fj := h[i].g+h[i].h, h[j].g+h[j].h
+	if fi != fj {
+		return fi < fj
+	}
+	return h[i].h < h[j].h
+}
+func (h openHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *openHeap) Push(x any)   { *h = append(*h, x.(openItem)) }
+func (h *openHeap) Pop() (x any) {
+	old := *h
+	n := len(old)
+	x = old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// FindPath runs A* for a single target on an n x n torus, using the
+// wraparound-corrected Manhattan distance as the heuristic and a bitmap
+// closed set. passable(p) reports whether p may be entered; it is never
+// consulted for start or target themselves. Returns the path from start
+// to target inclusive, or nil if target is unreachable.
+func FindPath(n int, start, target P, passable func(p P) bool) []P {
+	if start == target {
+		return []P{start}
+	}
+
+	size := n * n
+	closed := make([]bool, size)
+	hasG := make([]bool, size)
+	gScore := make([]int, size)
+	came := make([]P, size)
+
+	open := &openHeap{{pos: start, g: 0, h: heuristic(start, target, n)}}
+	hasG[idx(start, n)] = true
+
+	for open.Len() > 0 {
+		cur := heap.Pop(open).(openItem)
+		ci := idx(cur.pos, n)
+		if closed[ci] {
+			continue
+		}
+		closed[ci] = true
+
+		if cur.pos == target {
+			path := []P{cur.pos}
+			for p := cur.pos; p != start; {
+				p = came[idx(p, n)]
+				path = append([]P{p}, path...)
+			}
+			return path
+		}
+
+		for _, np := range torusNeighbors(cur.pos, n) {
+			ni := idx(np, n)
+			if closed[ni] {
+				continue
+			}
+			if np != target && !passable(np) {
+				continue
+			}
+			ng := cur.g + 1
+			if hasG[ni] && gScore[ni] <= ng {
+				continue
+			}
+			gScore[ni], hasG[ni], came[ni] = ng, true, cur.pos
+			heap.Push(open, openItem{pos: np, g: ng, h: heuristic(np, target, n)})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package behavior
+
+// randomShark replicates the original hardcoded shark behavior: eat a
+// random adjacent fish if any, else move to a random empty neighbor
+// (weighted by FishScent when scent is enabled), else stay.
+type randomShark struct{}
+
+func newRandomShark() AgentBehavior { return randomShark{} }
+
+func (randomShark) Plan(ctx BehaviorCtx) Action {
+	var fish, empty []Neighbor
+	for _, nb := range ctx.Neighbors {
+		switch nb.Kind {
+		case FishCell:
+			fish = append(fish, nb)
+		case Empty:
+			empty = append(empty, nb)
+		}
+	}
+	if len(fish) > 0 {
+		return Action{Kind: Eat, To: fish[ctx.RandIntn(len(fish))].Pos}
+	}
+	if len(empty) == 0 {
+		return Action{Kind: Stay}
+	}
+	i := ctx.RandIntn(len(empty))
+	if ctx.ScentEnabled {
+		weights := make([]float32, len(empty))
+		for j, nb := range empty {
+			weights[j] = ctx.FishScent(nb.Pos.X, nb.Pos.Y)
+		}
+		i = weightedChoice(ctx.RandFloat32, weights)
+	}
+	return Action{Kind: Move, To: empty[i].Pos}
+}
+
+func (randomShark) OnStep(ctx BehaviorCtx) {}
+
+// randomFish replicates the original hardcoded fish behavior: move to a
+// random empty neighbor (weighted by the inverse of SharkScent when
+// scent is enabled), else stay.
+type randomFish struct{}
+
+func newRandomFish() AgentBehavior { return randomFish{} }
+
+func (randomFish) Plan(ctx BehaviorCtx) Action {
+	var empty []Neighbor
+	for _, nb := range ctx.Neighbors {
+		if nb.Kind == Empty {
+			empty = append(empty, nb)
+		}
+	}
+	if len(empty) == 0 {
+		return Action{Kind: Stay}
+	}
+	i := ctx.RandIntn(len(empty))
+	if ctx.ScentEnabled {
+		weights := make([]float32, len(empty))
+		for j, nb := range empty {
+			weights[j] = 1 / (1 + ctx.SharkScent(nb.Pos.X, nb.Pos.Y))
+		}
+		i = weightedChoice(ctx.RandFloat32, weights)
+	}
+	return Action{Kind: Move, To: empty[i].Pos}
+}
+
+func (randomFish) OnStep(ctx BehaviorCtx) {}
@@ -9,9 +9,13 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"os"
 	"runtime"
 	"strconv"
 	"time"
+
+	"github.com/YimiaoHao/wator-project/behavior"
+	"github.com/YimiaoHao/wator-project/record"
 )
 
 // parsePositionalArgsIntoFlags parses the 7 required positional arguments into configuration flags.
@@ -63,6 +67,26 @@ func main() {
 	statsEvery := flag.Int("statsEvery", 0, "print stats every N steps (0 = never)")
 	quiet := flag.Bool("quiet", false, "suppress console prints")
 	nogui := flag.Bool("nogui", false, "force disable GUI even if -gui is set")
+	scent := flag.Bool("scent", false, "enable pheromone scent trails (sharks hunt by fish scent, fish flee shark scent)")
+	sharkAI := flag.String("shark-ai", "random", "shark behavior: random, greedy, astar, or policy")
+	fishAI := flag.String("fish-ai", "random", "fish behavior: random, greedy, or astar")
+	sight := flag.Int("sight", 5, "sight radius (cells) for the astar behaviors")
+	policyPath := flag.String("policy", "", "trained shark policy weights: read when -shark-ai=policy, written by -train")
+	train := flag.Bool("train", false, "train a shark policy via self-play REINFORCE instead of running the simulation, then exit")
+	episodes := flag.Int("episodes", 200, "number of training episodes (only with -train)")
+
+	terrainFile := flag.String("terrain", "", "load an ASCII terrain map from this file (overrides -size to match the map)")
+	terrainGen := flag.Bool("terrain-gen", false, "procedurally scatter reef terrain via cellular-automaton smoothing")
+	reefProb := flag.Float64("reef-prob", 0.4, "initial probability a cell is reef, before smoothing (-terrain-gen)")
+	reefPasses := flag.Int("reef-passes", 4, "cellular-automaton smoothing passes (-terrain-gen)")
+	reefBirth := flag.Int("reef-birth", 5, "reef neighbor count (of 8) for an open cell to become reef (-terrain-gen)")
+	reefSurvive := flag.Int("reef-survive", 4, "reef neighbor count (of 8) for a reef cell to remain reef (-terrain-gen)")
+
+	recordPath := flag.String("record", "", "write a deterministic recording of this run to this file (headless mode only)")
+	replayPath := flag.String("replay", "", "play back a recording instead of simulating")
+	replayFPS := flag.Int("replay-fps", 30, "playback speed in frames per second (only with -replay, GUI mode)")
+	statsCmd := flag.Bool("stats", false, "with -replay, print per-frame population/energy stats instead of opening the GUI")
+	verifyPath := flag.String("verify", "", "re-run a recording with StepSeq and confirm every frame matches, then exit")
 
 	flag.Parse()
 
@@ -72,6 +96,61 @@ func main() {
 	// Set random seed
 	rand.Seed(*seed)
 
+	// Concurrency setting
+	runtime.GOMAXPROCS(*workers)
+
+	// Behavior/terrain config: applied to package globals (and
+	// ActiveTerrain) before the -verify/-replay dispatch below, since
+	// runReplayGUI builds its own World and relies on ActiveTerrain
+	// already being in effect; RunVerify overwrites these globals from
+	// the recording's Header.
+	ScentEnabled = *scent
+	Sight = *sight
+	var err error
+	SharkAI, err = NewSharkAI(*sharkAI, *policyPath, *workers)
+	if err != nil {
+		log.Fatalf("-shark-ai=policy requires -policy <weights file>: %v", err)
+	}
+	FishAI = behavior.NewFish(*fishAI)
+
+	// Terrain setup: a loaded map dictates grid size; generated reef
+	// keeps the configured -size.
+	switch {
+	case *terrainFile != "":
+		t, err := LoadTerrain(*terrainFile)
+		if err != nil {
+			log.Fatalf("-terrain: %v", err)
+		}
+		*size = len(t)
+		ActiveTerrain = t
+	case *terrainGen:
+		ActiveTerrain = GenTerrain(*size, float32(*reefProb), *reefPasses, *reefBirth, *reefSurvive)
+	}
+
+	// -verify and -replay read their grid/rule-constant config from the
+	// recording itself, so they bypass the rest of flag handling
+	// entirely. -verify reconstructs its own scent/AI/terrain config from
+	// the recording's Header; -replay only needs ActiveTerrain (set
+	// above) to render the terrain layer, since it has no AI to run.
+	if *verifyPath != "" {
+		if err := RunVerify(*verifyPath); err != nil {
+			log.Fatalf("-verify: %v", err)
+		}
+		return
+	}
+	if *replayPath != "" {
+		if *statsCmd {
+			if err := RunStats(*replayPath); err != nil {
+				log.Fatalf("-replay -stats: %v", err)
+			}
+			return
+		}
+		if err := runReplayGUI(*replayPath, *replayFPS); err != nil {
+			log.Fatalf("-replay: %v", err)
+		}
+		return
+	}
+
 	// Basic parameter validation
 	if *workers < 1 {
 		log.Fatalf("workers must be >= 1, got %d", *workers)
@@ -90,6 +169,16 @@ func main() {
 			FishBreedSteps, SharkBreedSteps, SharkEnergyInit)
 	}
 
+	// Headless training mode: train a shark policy and exit, skipping the
+	// GUI/terminal simulation loop entirely.
+	if *train {
+		if *policyPath == "" {
+			log.Fatalf("-train requires -policy <weights file> to write trained weights to")
+		}
+		RunTrain(*size, *fish, *episodes, *steps, *workers, *policyPath)
+		return
+	}
+
 	// GUI toggle logic
 	if *nogui {
 		*gui = false
@@ -98,12 +187,15 @@ func main() {
 		*statsEvery = 0 // GUI mode: suppress step printing
 		*quiet = true
 	}
-
-	// Concurrency setting
-	runtime.GOMAXPROCS(*workers)
+	if *recordPath != "" && *gui {
+		log.Fatalf("-record only supports headless runs (drop -gui)")
+	}
 
 	// Initialize world
 	w := NewWorld(*size)
+	if ActiveTerrain != nil {
+		w.Terrain = ActiveTerrain
+	}
 	SeedRandom(w, *fish, *sharks)
 
 	if !*quiet {
@@ -111,6 +203,40 @@ func main() {
 			*sharks, *fish, FishBreedSteps, SharkBreedSteps, SharkEnergyInit, *size, *workers, *mode, *gui, *seed)
 	}
 
+	// Recording: one Writer for the whole run, fed a frame before the
+	// first step and after every subsequent one.
+	var rec *record.Writer
+	if *recordPath != "" {
+		rf, err := os.Create(*recordPath)
+		if err != nil {
+			log.Fatalf("-record: %v", err)
+		}
+		defer rf.Close()
+		h := record.Header{
+			Size: int32(*size), NumFish: int32(*fish), NumSharks: int32(*sharks),
+			FishBreedSteps: int32(FishBreedSteps), SharkBreedSteps: int32(SharkBreedSteps),
+			SharkEnergyInit: int32(SharkEnergyInit), SharkEnergyGain: int32(SharkEnergyGain), SharkEnergyLoss: int32(SharkEnergyLoss),
+			Seed: *seed, Mode: *mode, Workers: int32(*workers), KeyframeEvery: 50,
+			ScentEnabled: *scent, Sight: int32(*sight),
+			SharkAI: *sharkAI, FishAI: *fishAI, SharkPolicy: *policyPath,
+		}
+		switch {
+		case *terrainFile != "":
+			h.TerrainMode = "file"
+			h.TerrainGrid = terrainToBytes(ActiveTerrain)
+		case *terrainGen:
+			h.TerrainMode = "gen"
+			h.ReefProb, h.ReefPasses, h.ReefBirth, h.ReefSurvive = float32(*reefProb), int32(*reefPasses), int32(*reefBirth), int32(*reefSurvive)
+		}
+		rec, err = record.NewWriter(rf, h)
+		if err != nil {
+			log.Fatalf("-record: %v", err)
+		}
+		if err := rec.WriteFrame(snapshotCells(w)); err != nil {
+			log.Fatalf("-record: %v", err)
+		}
+	}
+
 	// GUI mode branch
 	if *gui {
 		runMode := *mode
@@ -133,6 +259,12 @@ func main() {
 			w = StepSeq(w)
 		}
 
+		if rec != nil {
+			if err := rec.WriteFrame(snapshotCells(w)); err != nil {
+				log.Fatalf("-record: %v", err)
+			}
+		}
+
 		if !*quiet && *statsEvery > 0 && (i%*statsEvery == 0) {
 			f, s := Count(w)
 			fmt.Printf("step=%03d  fish=%5d  sharks=%5d\n", i, f, s)
@@ -0,0 +1,89 @@
+package policy
+
+import "github.com/YimiaoHao/wator-project/behavior"
+
+// Window is the half-size of the square neighborhood Encode reads
+// around an agent; the encoded window is (2*Window+1) cells per side.
+const Window = 2
+
+// directions maps a non-Stay action index (1..4) to the same N/E/S/W
+// offsets and order as main.neigh4.
+var directions = [4]offset{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+
+// offset is a plain (dx, dy) delta, distinct from behavior.P which is an
+// absolute torus coordinate.
+type offset struct{ X, Y int }
+
+// Encode builds the network input for ctx: a one-hot occupant encoding
+// (Empty, FishCell, SharkCell) for every cell in the (2*Window+1) square
+// centered on the agent, followed by its normalized energy and
+// breed-timer.
+func Encode(ctx behavior.BehaviorCtx) []float32 {
+	k := 2*Window + 1
+	in := make([]float32, k*k*3+2)
+
+	i := 0
+	for dy := -Window; dy <= Window; dy++ {
+		for dx := -Window; dx <= Window; dx++ {
+			kind := ctx.Grid(ctx.Self.X+dx, ctx.Self.Y+dy)
+			in[i+int(kind)] = 1
+			i += 3
+		}
+	}
+	in[i] = float32(ctx.Energy) / float32(ctx.BreedSteps+1)
+	breedSteps := ctx.BreedSteps
+	if breedSteps < 1 {
+		breedSteps = 1
+	}
+	in[i+1] = float32(ctx.BreedTimer) / float32(breedSteps)
+	return in
+}
+
+// ActionFromIndex turns a network action index (0 = Stay, 1..4 = the
+// directions above) into a behavior.Action, looking at ctx.Neighbors to
+// tell a move from an eat and falling back to Stay if the chosen
+// direction has no legal candidate this chronon (e.g. it is claimed by
+// another agent).
+func ActionFromIndex(ctx behavior.BehaviorCtx, a int) behavior.Action {
+	if a == 0 {
+		return behavior.Action{Kind: behavior.Stay}
+	}
+	d := directions[a-1]
+	to := behavior.P{
+		X: mod(ctx.Self.X+d.X, ctx.Size),
+		Y: mod(ctx.Self.Y+d.Y, ctx.Size),
+	}
+	for _, nb := range ctx.Neighbors {
+		if nb.Pos != to {
+			continue
+		}
+		if nb.Kind == behavior.FishCell {
+			return behavior.Action{Kind: behavior.Eat, To: to}
+		}
+		return behavior.Action{Kind: behavior.Move, To: to}
+	}
+	return behavior.Action{Kind: behavior.Stay}
+}
+
+func mod(i, n int) int { return ((i % n) + n) % n }
+
+// SharkPolicy is an AgentBehavior backed by a trained Net. Once deployed
+// it runs greedily (always the highest-probability action); training
+// instead samples directly via Net, Encode and ActionFromIndex so it can
+// record the trajectory fed into Net.Update (see the -train flag).
+type SharkPolicy struct {
+	Net     *Net
+	Workers int
+}
+
+// NewSharkPolicy wraps a trained Net as a deployable shark AgentBehavior.
+func NewSharkPolicy(n *Net, workers int) *SharkPolicy {
+	return &SharkPolicy{Net: n, Workers: workers}
+}
+
+func (p *SharkPolicy) Plan(ctx behavior.BehaviorCtx) behavior.Action {
+	logits := p.Net.Forward(Encode(ctx), p.Workers)
+	return ActionFromIndex(ctx, Argmax(logits))
+}
+
+func (p *SharkPolicy) OnStep(ctx behavior.BehaviorCtx) {}
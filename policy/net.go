@@ -0,0 +1,125 @@
+// Package policy implements a small feed-forward neural network that can
+// be trained, via self-play REINFORCE, to replace the hardcoded shark
+// behaviors in package behavior. It depends only on the standard library
+// and on package behavior (for the BehaviorCtx it encodes), never on
+// package main, so it stays reusable from both the simulation and a
+// standalone training loop.
+package policy
+
+import (
+	"encoding/gob"
+	"io"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// NumActions is the size of the network's output layer: Stay, plus the
+// four neigh4 directions (North, East, South, West), in that order.
+const NumActions = 5
+
+// Net is a two-layer feed-forward network (input -> ReLU hidden ->
+// logits over NumActions). Weights are stored flattened, row-major, so
+// they gob-encode and decode without custom marshaling.
+type Net struct {
+	InputSize  int
+	HiddenSize int
+	W1         []float32 // HiddenSize x InputSize
+	B1         []float32 // HiddenSize
+	W2         []float32 // NumActions x HiddenSize
+	B2         []float32 // NumActions
+}
+
+// NewNet builds a randomly-initialized network sized for the Encode
+// window radius (see Encode): InputSize = (2*window+1)^2*3 + 2.
+func NewNet(window int) *Net {
+	k := 2*window + 1
+	in := k*k*3 + 2
+	const hidden = 32
+
+	return &Net{
+		InputSize:  in,
+		HiddenSize: hidden,
+		W1:         randWeights(hidden*in, in),
+		B1:         make([]float32, hidden),
+		W2:         randWeights(NumActions*hidden, hidden),
+		B2:         make([]float32, NumActions),
+	}
+}
+
+// randWeights fills a fanOut*fanIn matrix with small random values
+// scaled by 1/sqrt(fanIn), a standard initialization for ReLU networks.
+func randWeights(size, fanIn int) []float32 {
+	scale := float32(1) / float32(math.Sqrt(float64(fanIn)))
+	w := make([]float32, size)
+	for i := range w {
+		w[i] = (rand.Float32()*2 - 1) * scale
+	}
+	return w
+}
+
+// Forward computes the network's output logits for x, splitting each
+// layer's matrix-vector product across workers goroutines (reusing the
+// simulation's -workers count; pass 1 for a sequential pass).
+func (n *Net) Forward(x []float32, workers int) []float32 {
+	hidden := matVec(n.W1, n.B1, x, n.HiddenSize, n.InputSize, workers)
+	for i, v := range hidden {
+		if v < 0 {
+			hidden[i] = 0
+		}
+	}
+	return matVec(n.W2, n.B2, hidden, NumActions, n.HiddenSize, workers)
+}
+
+// matVec computes w*x+b for a rows x cols row-major matrix w, splitting
+// the rows evenly across workers goroutines.
+func matVec(w, b, x []float32, rows, cols, workers int) []float32 {
+	out := make([]float32, rows)
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > rows {
+		workers = rows
+	}
+
+	chunk := (rows + workers - 1) / workers
+	var wg sync.WaitGroup
+	for wi := 0; wi < workers; wi++ {
+		r0 := wi * chunk
+		r1 := r0 + chunk
+		if r1 > rows {
+			r1 = rows
+		}
+		if r0 >= r1 {
+			continue
+		}
+		wg.Add(1)
+		go func(r0, r1 int) {
+			defer wg.Done()
+			for r := r0; r < r1; r++ {
+				var sum float32
+				base := r * cols
+				for c := 0; c < cols; c++ {
+					sum += w[base+c] * x[c]
+				}
+				out[r] = sum + b[r]
+			}
+		}(r0, r1)
+	}
+	wg.Wait()
+	return out
+}
+
+// Save gob-encodes the network's weights to w.
+func (n *Net) Save(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(n)
+}
+
+// Load decodes a network previously written by Save.
+func Load(r io.Reader) (*Net, error) {
+	var n Net
+	if err := gob.NewDecoder(r).Decode(&n); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
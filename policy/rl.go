@@ -0,0 +1,109 @@
+package policy
+
+import "math"
+
+// Softmax converts logits into a probability distribution.
+func Softmax(logits []float32) []float32 {
+	max := logits[0]
+	for _, v := range logits[1:] {
+		if v > max {
+			max = v
+		}
+	}
+	probs := make([]float32, len(logits))
+	var sum float32
+	for i, v := range logits {
+		probs[i] = float32(math.Exp(float64(v - max)))
+		sum += probs[i]
+	}
+	for i := range probs {
+		probs[i] /= sum
+	}
+	return probs
+}
+
+// Sample draws an index from probs, using f (typically ctx.RandFloat32)
+// as the source of randomness.
+func Sample(probs []float32, f func() float32) int {
+	r := f()
+	for i, p := range probs {
+		r -= p
+		if r <= 0 {
+			return i
+		}
+	}
+	return len(probs) - 1
+}
+
+// Argmax returns the index of the largest value in v.
+func Argmax(v []float32) int {
+	best := 0
+	for i, x := range v[1:] {
+		if x > v[best] {
+			best = i + 1
+		}
+	}
+	return best
+}
+
+// Step is one (state, action, return) sample of a trajectory, ready for
+// Update. Return is the discounted cumulative reward from this step to
+// the end of the episode, not the immediate reward.
+type Step struct {
+	Input  []float32
+	Action int
+	Return float32
+}
+
+// Update performs one REINFORCE gradient-ascent pass over trajectory,
+// nudging the log-probability of each taken action in proportion to its
+// Return. A positive Return increases the action's probability; a
+// negative one decreases it.
+func (n *Net) Update(trajectory []Step, lr float32, workers int) {
+	for _, st := range trajectory {
+		hidden := matVec(n.W1, n.B1, st.Input, n.HiddenSize, n.InputSize, workers)
+		reluMask := make([]float32, len(hidden))
+		for i, v := range hidden {
+			if v > 0 {
+				reluMask[i] = 1
+			} else {
+				hidden[i] = 0
+			}
+		}
+		logits := matVec(n.W2, n.B2, hidden, NumActions, n.HiddenSize, workers)
+		probs := Softmax(logits)
+
+		// d(log pi(a))/d(logits) = indicator(a) - probs; scale by Return
+		// to turn it into the REINFORCE gradient-ascent direction.
+		dLogits := make([]float32, NumActions)
+		for i, p := range probs {
+			target := float32(0)
+			if i == st.Action {
+				target = 1
+			}
+			dLogits[i] = (target - p) * st.Return
+		}
+
+		dHidden := make([]float32, n.HiddenSize)
+		for o, g := range dLogits {
+			base := o * n.HiddenSize
+			for h := 0; h < n.HiddenSize; h++ {
+				dHidden[h] += g * n.W2[base+h]
+				n.W2[base+h] += lr * g * hidden[h]
+			}
+			n.B2[o] += lr * g
+		}
+
+		for h, dh := range dHidden {
+			dh *= reluMask[h]
+			if dh == 0 {
+				continue
+			}
+			base := h * n.InputSize
+			for i, xi := range st.Input {
+				n.W1[base+i] += lr * dh * xi
+			}
+			n.B1[h] += lr * dh
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package record serializes a Wa-Tor simulation run to a compact binary
+// stream (".wtr") and reads it back, so a run can be replayed frame for
+// frame without re-simulating it, or re-simulated and diffed against the
+// recording to check determinism. It has no dependency on package main:
+// callers translate their own World/Cell types to and from CellState.
+package record
+
+// Magic identifies a .wtr stream; Version lets the format change later
+// without silently misreading older recordings.
+const (
+	Magic   uint32 = 0x57544F52 // "WTOR"
+	Version uint16 = 1
+)
+
+// Header is written once, before any frames. It captures everything
+// needed to either replay the recording verbatim or re-simulate it from
+// scratch for comparison (see -verify in main.go).
+type Header struct {
+	Size            int32
+	NumFish         int32 // SeedRandom's initial fish count
+	NumSharks       int32 // SeedRandom's initial shark count
+	FishBreedSteps  int32
+	SharkBreedSteps int32
+	SharkEnergyInit int32
+	SharkEnergyGain int32
+	SharkEnergyLoss int32
+	Seed            int64
+	Mode            string // "seq" or "par"
+	Workers         int32  // only meaningful for Mode == "par"
+	KeyframeEvery   int32  // write a full snapshot every Nth frame (see Writer)
+
+	// The fields below mirror the -scent/-shark-ai/-fish-ai/-sight/
+	// -terrain*/-policy flags in effect when the recording was made, so
+	// -verify can reconstruct the exact run instead of depending on the
+	// operator re-passing identical flags.
+	ScentEnabled bool
+	Sight        int32
+	SharkAI      string // "random", "greedy", "astar", or "policy"
+	FishAI       string // "random", "greedy", or "astar"
+	SharkPolicy  string // weights file path; only meaningful when SharkAI == "policy"
+
+	// TerrainMode is "" (no terrain), "file" (-terrain), or "gen"
+	// (-terrain-gen). For "file", TerrainGrid carries the loaded grid
+	// directly, since re-reading the original file would both depend on
+	// it still existing and consume no RNG draws anyway. For "gen",
+	// ReefProb/ReefPasses/ReefBirth/ReefSurvive carry GenTerrain's
+	// parameters instead: GenTerrain consumes the global math/rand
+	// stream, so -verify must call it again at the same point in the
+	// sequence (right after re-seeding, before SeedRandom) to keep every
+	// later draw aligned with the recording, rather than just reusing a
+	// stored grid.
+	TerrainMode string
+	TerrainGrid []byte // row-major Terrain bytes, len Size*Size; only set for TerrainMode == "file"
+	ReefProb    float32
+	ReefPasses  int32
+	ReefBirth   int32
+	ReefSurvive int32
+}
+
+// IsDeterministicReplay reports whether a replay/verify run uses the
+// same Mode and, for "par", the same Workers the recording was made
+// with. StepPar seeds each row segment with stepSeed+segmentIndex, and
+// splitRows' segment boundaries depend on the worker count, so a match
+// here is required for StepPar to reproduce the recording; it is not
+// sufficient, since workers still race for cells at segment boundaries.
+func (h Header) IsDeterministicReplay(mode string, workers int) bool {
+	if h.Mode != mode {
+		return false
+	}
+	if h.Mode == "par" && int(h.Workers) != workers {
+		return false
+	}
+	return true
+}
+
+// CellType is the occupant kind of one recorded cell.
+type CellType byte
+
+const (
+	CellEmpty CellType = iota
+	CellFish
+	CellShark
+)
+
+// CellState is one cell's full state: occupant kind plus whichever
+// fields apply (BreedTimer for Fish/Shark, Energy for Shark only).
+type CellState struct {
+	Type       CellType
+	BreedTimer int
+	Energy     int
+}
+
+// frame tags, written as the first byte of every frame.
+const (
+	tagKeyframe byte = iota
+	tagDelta
+)
+
+// cellChange is one entry in a delta frame: the row-major index of a
+// cell whose state differs from the previous frame, plus its new state.
+type cellChange struct {
+	Index int
+	State CellState
+}
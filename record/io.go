@@ -0,0 +1,230 @@
+package record
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// writeHeader writes h in a fixed binary layout: Magic, Version, then
+// the numeric fields, then the Mode/SharkAI/FishAI/SharkPolicy/
+// TerrainMode strings (each length-prefixed), then TerrainGrid (also
+// length-prefixed, possibly empty).
+func writeHeader(w io.Writer, h Header) error {
+	if err := binary.Write(w, binary.LittleEndian, Magic); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, Version); err != nil {
+		return err
+	}
+	fields := []int32{
+		h.Size, h.NumFish, h.NumSharks, h.FishBreedSteps, h.SharkBreedSteps,
+		h.SharkEnergyInit, h.SharkEnergyGain, h.SharkEnergyLoss,
+	}
+	for _, f := range fields {
+		if err := binary.Write(w, binary.LittleEndian, f); err != nil {
+			return err
+		}
+	}
+	if err := binary.Write(w, binary.LittleEndian, h.Seed); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, h.Workers); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, h.KeyframeEvery); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, h.ScentEnabled); err != nil {
+		return err
+	}
+	moreFields := []int32{h.Sight, h.ReefPasses, h.ReefBirth, h.ReefSurvive}
+	for _, f := range moreFields {
+		if err := binary.Write(w, binary.LittleEndian, f); err != nil {
+			return err
+		}
+	}
+	if err := binary.Write(w, binary.LittleEndian, h.ReefProb); err != nil {
+		return err
+	}
+	if err := writeString(w, h.Mode); err != nil {
+		return err
+	}
+	if err := writeString(w, h.SharkAI); err != nil {
+		return err
+	}
+	if err := writeString(w, h.FishAI); err != nil {
+		return err
+	}
+	if err := writeString(w, h.SharkPolicy); err != nil {
+		return err
+	}
+	if err := writeString(w, h.TerrainMode); err != nil {
+		return err
+	}
+	return writeBytes(w, h.TerrainGrid)
+}
+
+// readHeader is writeHeader's inverse.
+func readHeader(r io.Reader) (Header, error) {
+	var h Header
+	var magic uint32
+	var version uint16
+	if err := binary.Read(r, binary.LittleEndian, &magic); err != nil {
+		return h, err
+	}
+	if magic != Magic {
+		return h, fmt.Errorf("record: not a .wtr stream (bad magic %#x)", magic)
+	}
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return h, err
+	}
+	if version != Version {
+		return h, fmt.Errorf("record: unsupported version %d (want %d)", version, Version)
+	}
+	fields := []*int32{
+		&h.Size, &h.NumFish, &h.NumSharks, &h.FishBreedSteps, &h.SharkBreedSteps,
+		&h.SharkEnergyInit, &h.SharkEnergyGain, &h.SharkEnergyLoss,
+	}
+	for _, f := range fields {
+		if err := binary.Read(r, binary.LittleEndian, f); err != nil {
+			return h, err
+		}
+	}
+	if err := binary.Read(r, binary.LittleEndian, &h.Seed); err != nil {
+		return h, err
+	}
+	if err := binary.Read(r, binary.LittleEndian, &h.Workers); err != nil {
+		return h, err
+	}
+	if err := binary.Read(r, binary.LittleEndian, &h.KeyframeEvery); err != nil {
+		return h, err
+	}
+	if err := binary.Read(r, binary.LittleEndian, &h.ScentEnabled); err != nil {
+		return h, err
+	}
+	moreFields := []*int32{&h.Sight, &h.ReefPasses, &h.ReefBirth, &h.ReefSurvive}
+	for _, f := range moreFields {
+		if err := binary.Read(r, binary.LittleEndian, f); err != nil {
+			return h, err
+		}
+	}
+	if err := binary.Read(r, binary.LittleEndian, &h.ReefProb); err != nil {
+		return h, err
+	}
+	var err error
+	if h.Mode, err = readString(r); err != nil {
+		return h, err
+	}
+	if h.SharkAI, err = readString(r); err != nil {
+		return h, err
+	}
+	if h.FishAI, err = readString(r); err != nil {
+		return h, err
+	}
+	if h.SharkPolicy, err = readString(r); err != nil {
+		return h, err
+	}
+	if h.TerrainMode, err = readString(r); err != nil {
+		return h, err
+	}
+	if h.TerrainGrid, err = readBytes(r); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
+// writeString/readString write a byte slice preceded by its length as a
+// uint32 (Mode et al. are short, but TerrainGrid can exceed a uint8's
+// 255-byte limit for any grid wider than ~16 cells).
+func writeString(w io.Writer, s string) error {
+	return writeBytes(w, []byte(s))
+}
+
+func readString(r io.Reader) (string, error) {
+	b, err := readBytes(r)
+	return string(b), err
+}
+
+func writeBytes(w io.Writer, b []byte) error {
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(b))); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+func readBytes(r io.Reader) ([]byte, error) {
+	var n uint32
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// writeUvarint/readUvarint wrap encoding/binary's varint helpers with
+// the io.Writer/io.ByteReader types this package uses throughout.
+func writeUvarint(w io.Writer, buf []byte, v uint64) error {
+	n := binary.PutUvarint(buf, v)
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+func readUvarint(r io.ByteReader) (uint64, error) {
+	return binary.ReadUvarint(r)
+}
+
+// writeCellState writes a single occupant byte, followed by whichever
+// varint fields its Type implies (none for Empty, BreedTimer for Fish,
+// BreedTimer+Energy for Shark).
+func writeCellState(w io.Writer, buf []byte, c CellState) error {
+	if _, err := w.Write([]byte{byte(c.Type)}); err != nil {
+		return err
+	}
+	switch c.Type {
+	case CellFish:
+		return writeUvarint(w, buf, uint64(c.BreedTimer))
+	case CellShark:
+		if err := writeUvarint(w, buf, uint64(c.BreedTimer)); err != nil {
+			return err
+		}
+		return writeUvarint(w, buf, uint64(c.Energy))
+	}
+	return nil
+}
+
+// readCellState is writeCellState's inverse; r must also implement
+// io.ByteReader (bufio.Reader satisfies both).
+func readCellState(r interface {
+	io.Reader
+	io.ByteReader
+}) (CellState, error) {
+	var tb [1]byte
+	if _, err := io.ReadFull(r, tb[:]); err != nil {
+		return CellState{}, err
+	}
+	c := CellState{Type: CellType(tb[0])}
+	switch c.Type {
+	case CellFish:
+		bt, err := readUvarint(r)
+		if err != nil {
+			return CellState{}, err
+		}
+		c.BreedTimer = int(bt)
+	case CellShark:
+		bt, err := readUvarint(r)
+		if err != nil {
+			return CellState{}, err
+		}
+		energy, err := readUvarint(r)
+		if err != nil {
+			return CellState{}, err
+		}
+		c.BreedTimer, c.Energy = int(bt), int(energy)
+	}
+	return c, nil
+}
@@ -0,0 +1,78 @@
+package record
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Reader reads frames written by Writer, reconstructing each one from
+// either a keyframe or a delta against the previously read frame.
+type Reader struct {
+	r    *bufio.Reader
+	n    int // cells per frame (Header.Size^2)
+	prev []CellState
+}
+
+// NewReader reads the header from r and returns a Reader ready for
+// ReadFrame calls, along with the decoded Header.
+func NewReader(r io.Reader) (*Reader, Header, error) {
+	h, err := readHeader(r)
+	if err != nil {
+		return nil, Header{}, err
+	}
+	n := int(h.Size) * int(h.Size)
+	return &Reader{r: bufio.NewReader(r), n: n}, h, nil
+}
+
+// ReadFrame returns the next frame's cells (row-major, length n from
+// NewReader), or io.EOF once the stream is exhausted.
+func (rd *Reader) ReadFrame() ([]CellState, error) {
+	tag, err := rd.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	case tagKeyframe:
+		cells := make([]CellState, rd.n)
+		for i := range cells {
+			c, err := readCellState(rd.r)
+			if err != nil {
+				return nil, err
+			}
+			cells[i] = c
+		}
+		rd.prev = cells
+		return cells, nil
+
+	case tagDelta:
+		if rd.prev == nil {
+			return nil, fmt.Errorf("record: delta frame with no prior keyframe")
+		}
+		count, err := readUvarint(rd.r)
+		if err != nil {
+			return nil, err
+		}
+		cells := append([]CellState(nil), rd.prev...)
+		for i := uint64(0); i < count; i++ {
+			idx, err := readUvarint(rd.r)
+			if err != nil {
+				return nil, err
+			}
+			c, err := readCellState(rd.r)
+			if err != nil {
+				return nil, err
+			}
+			if int(idx) >= len(cells) {
+				return nil, fmt.Errorf("record: delta index %d out of range (n=%d)", idx, len(cells))
+			}
+			cells[idx] = c
+		}
+		rd.prev = cells
+		return cells, nil
+
+	default:
+		return nil, fmt.Errorf("record: unknown frame tag %d", tag)
+	}
+}
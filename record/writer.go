@@ -0,0 +1,82 @@
+package record
+
+import "io"
+
+// Writer streams frames to an underlying io.Writer: the first frame and
+// every Header.KeyframeEvery-th one after it are written as a full
+// keyframe; every other frame is a delta against the previous one.
+type Writer struct {
+	w       io.Writer
+	header  Header
+	prev    []CellState
+	frameNo int
+	buf     []byte // scratch space for varint encoding
+}
+
+// NewWriter writes h and returns a Writer ready for WriteFrame calls.
+func NewWriter(w io.Writer, h Header) (*Writer, error) {
+	if h.KeyframeEvery < 1 {
+		h.KeyframeEvery = 1
+	}
+	if err := writeHeader(w, h); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, header: h, buf: make([]byte, 10)}, nil
+}
+
+// WriteFrame appends one chronon's cells (row-major, length
+// Header.Size*Header.Size) to the stream.
+func (wr *Writer) WriteFrame(cells []CellState) error {
+	keyframe := wr.prev == nil || wr.frameNo%int(wr.header.KeyframeEvery) == 0
+
+	var err error
+	if keyframe {
+		err = wr.writeKeyframe(cells)
+	} else {
+		err = wr.writeDelta(cells)
+	}
+	if err != nil {
+		return err
+	}
+
+	wr.prev = append(wr.prev[:0], cells...)
+	wr.frameNo++
+	return nil
+}
+
+func (wr *Writer) writeKeyframe(cells []CellState) error {
+	if _, err := wr.w.Write([]byte{tagKeyframe}); err != nil {
+		return err
+	}
+	for _, c := range cells {
+		if err := writeCellState(wr.w, wr.buf, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (wr *Writer) writeDelta(cells []CellState) error {
+	var changes []cellChange
+	for i, c := range cells {
+		if c != wr.prev[i] {
+			changes = append(changes, cellChange{Index: i, State: c})
+		}
+	}
+
+	if _, err := wr.w.Write([]byte{tagDelta}); err != nil {
+		return err
+	}
+	if err := writeUvarint(wr.w, wr.buf, uint64(len(changes))); err != nil {
+		return err
+	}
+	for _, ch := range changes {
+		if err := writeUvarint(wr.w, wr.buf, uint64(ch.Index)); err != nil {
+			return err
+		}
+		if err := writeCellState(wr.w, wr.buf, ch.State); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import "github.com/YimiaoHao/wator-project/record"
+
+// snapshotCells flattens w's occupant state into row-major record
+// cells, the form record.Writer/record.Reader exchange with callers.
+func snapshotCells(w *World) []record.CellState {
+	cells := make([]record.CellState, w.Size*w.Size)
+	i := 0
+	for y := 0; y < w.Size; y++ {
+		for x := 0; x < w.Size; x++ {
+			switch c := w.Grid[y][x]; c.Type {
+			case Fish:
+				cells[i] = record.CellState{Type: record.CellFish, BreedTimer: c.Fish.BreedTimer}
+			case Shark:
+				cells[i] = record.CellState{Type: record.CellShark, BreedTimer: c.Shark.BreedTimer, Energy: c.Shark.Energy}
+			}
+			i++
+		}
+	}
+	return cells
+}
+
+// applyCells writes row-major record cells back into w's grid, the
+// inverse of snapshotCells.
+func applyCells(w *World, cells []record.CellState) {
+	i := 0
+	for y := 0; y < w.Size; y++ {
+		for x := 0; x < w.Size; x++ {
+			switch cells[i].Type {
+			case record.CellFish:
+				w.Grid[y][x] = Cell{Type: Fish, Fish: &FishState{BreedTimer: cells[i].BreedTimer}}
+			case record.CellShark:
+				w.Grid[y][x] = Cell{Type: Shark, Shark: &SharkState{BreedTimer: cells[i].BreedTimer, Energy: cells[i].Energy}}
+			default:
+				w.Grid[y][x] = Cell{}
+			}
+			i++
+		}
+	}
+}
+
+// cellsEqual reports whether two row-major cell snapshots match exactly
+// (used by RunVerify to check StepSeq reproduces a recording).
+func cellsEqual(a, b []record.CellState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/YimiaoHao/wator-project/record"
+)
+
+// RunStats replays a recording headlessly, printing per-frame
+// population counts and a coarse shark-energy histogram instead of
+// rendering it — a way to compare runs without opening the GUI.
+func RunStats(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rd, h, err := record.NewReader(f)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("STATS size=%d mode=%s workers=%d seed=%d\n", h.Size, h.Mode, h.Workers, h.Seed)
+
+	for frame := 0; ; frame++ {
+		cells, err := rd.ReadFrame()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var fish, sharks int
+		var energyHist [5]int // buckets: [0,2) [2,4) [4,6) [6,8) [8,+)
+		for _, c := range cells {
+			switch c.Type {
+			case record.CellFish:
+				fish++
+			case record.CellShark:
+				sharks++
+				b := c.Energy / 2
+				if b > 4 {
+					b = 4
+				}
+				energyHist[b]++
+			}
+		}
+		fmt.Printf("frame=%04d fish=%6d sharks=%6d energyHist=%v\n", frame, fish, sharks, energyHist)
+	}
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"math/rand"
 	"sync"
+
+	"github.com/YimiaoHao/wator-project/behavior"
 )
 
 /**
@@ -63,7 +65,20 @@ func splitRows(nRows, workers int) [][2]int {
  */
 func StepPar(curr *World, workers int, stepSeed int64) *World {
 	n := curr.Size
+
+	if ScentEnabled {
+		DepositScent(curr)
+	}
+
 	next := NewWorld(n)
+	next.FishScent = curr.FishScent
+	next.SharkScent = curr.SharkScent
+	next.Terrain = curr.Terrain
+
+	gridFn := gridKindFn(curr.Grid, n)
+	fishScentFn := scentFn(curr.FishScent, n)
+	sharkScentFn := scentFn(curr.SharkScent, n)
+	passableFn := terrainPassableFn(curr.Terrain, n)
 
 	var wg sync.WaitGroup
 	// Row-level locks to manage concurrent writes to the 'next' grid
@@ -118,15 +133,28 @@ func StepPar(curr *World, workers int, stepSeed int64) *World {
 					ns := neigh4(x, y, n)
 
 					// Find candidates: Empty in 'curr' AND not yet occupied in 'next'
-					cands := make([]P, 0, 4)
+					var neighbors []behavior.Neighbor
 					for _, q := range ns {
+						if curr.Terrain[q.Y][q.X] == Reef {
+							continue
+						}
 						if curr.Grid[q.Y][q.X].Type == Empty && emptyNext(q.X, q.Y) {
-							cands = append(cands, q)
+							neighbors = append(neighbors, behavior.Neighbor{Pos: toBP(q), Kind: behavior.Empty})
 						}
 					}
+
+					ctx := behavior.BehaviorCtx{
+						Self: toBP(P{X: x, Y: y}), Size: n, Neighbors: neighbors,
+						BreedTimer: f.BreedTimer, BreedSteps: FishBreedSteps,
+						Sight: Sight, ScentEnabled: ScentEnabled,
+						Grid: gridFn, Passable: passableFn, FishScent: fishScentFn, SharkScent: sharkScentFn,
+						RandFloat32: rr.Float32, RandIntn: rr.Intn,
+					}
+					action := FishAI.Plan(ctx)
+
 					target := P{X: x, Y: y}
-					if len(cands) > 0 {
-						target = cands[rr.Intn(len(cands))]
+					if action.Kind == behavior.Move {
+						target = P{X: action.To.X, Y: action.To.Y}
 					}
 
 					if f.BreedTimer >= FishBreedSteps && (target.X != x || target.Y != y) {
@@ -140,6 +168,8 @@ func StepPar(curr *World, workers int, stepSeed int64) *World {
 						}
 					}
 
+					FishAI.OnStep(ctx)
+
 				case Shark:
 					s := *cell.Shark
 					s.BreedTimer++
@@ -149,35 +179,45 @@ func StepPar(curr *World, workers int, stepSeed int64) *World {
 					}
 
 					ns := neigh4(x, y, n)
-					fishC := make([]P, 0, 4)
+					var neighbors []behavior.Neighbor
 					for _, q := range ns {
-						// Look for fish in 'curr' that haven't been claimed in 'next'
-						if curr.Grid[q.Y][q.X].Type == Fish && emptyNext(q.X, q.Y) {
-							fishC = append(fishC, q)
+						if curr.Terrain[q.Y][q.X] == Reef {
+							continue
+						}
+						switch {
+						case curr.Grid[q.Y][q.X].Type == Fish && emptyNext(q.X, q.Y):
+							// Look for fish in 'curr' that haven't been claimed in 'next'
+							neighbors = append(neighbors, behavior.Neighbor{Pos: toBP(q), Kind: behavior.FishCell})
+						case curr.Grid[q.Y][q.X].Type == Empty && emptyNext(q.X, q.Y):
+							neighbors = append(neighbors, behavior.Neighbor{Pos: toBP(q), Kind: behavior.Empty})
 						}
 					}
+
+					ctx := behavior.BehaviorCtx{
+						Self: toBP(P{X: x, Y: y}), Size: n, Neighbors: neighbors,
+						Energy: s.Energy, BreedTimer: s.BreedTimer, BreedSteps: SharkBreedSteps,
+						Sight: Sight, ScentEnabled: ScentEnabled,
+						Grid: gridFn, Passable: passableFn, FishScent: fishScentFn, SharkScent: sharkScentFn,
+						RandFloat32: rr.Float32, RandIntn: rr.Intn,
+					}
+					sharkAction := SharkAI.Plan(ctx)
+
 					moved := false
 					tx, ty := x, y
 
-					if len(fishC) > 0 {
+					switch sharkAction.Kind {
+					case behavior.Eat:
 						// Eat fish: Move to fish location, gain energy
-						t := fishC[rr.Intn(len(fishC))]
-						tx, ty = t.X, t.Y
+						tx, ty = sharkAction.To.X, sharkAction.To.Y
 						s.Energy += SharkEnergyGain
 						if place(tx, ty, Cell{Type: Shark, Shark: &SharkState{BreedTimer: s.BreedTimer, Energy: s.Energy}}) {
 							moved = true
 						}
-					} else {
-						// No fish found: Try to move to an empty adjacent square
-						emptyC := make([]P, 0, 4)
-						for _, q := range ns {
-							if curr.Grid[q.Y][q.X].Type == Empty && emptyNext(q.X, q.Y) {
-								emptyC = append(emptyC, q)
-							}
-						}
-						if len(emptyC) > 0 {
-							t := emptyC[rr.Intn(len(emptyC))]
-							tx, ty = t.X, t.Y
+					default:
+						// Move to the chosen empty cell, or stay at (x, y)
+						// if nothing was offered/chosen.
+						if sharkAction.Kind == behavior.Move {
+							tx, ty = sharkAction.To.X, sharkAction.To.Y
 						}
 						if !place(tx, ty, Cell{Type: Shark, Shark: &SharkState{BreedTimer: s.BreedTimer, Energy: s.Energy}}) {
 							// If move failed (blocked), stay put
@@ -185,6 +225,8 @@ func StepPar(curr *World, workers int, stepSeed int64) *World {
 						}
 					}
 
+					SharkAI.OnStep(ctx)
+
 					// Breed: Only reproduce if the shark successfully moved
 					if s.BreedTimer >= SharkBreedSteps && moved {
 						_ = place(x, y, Cell{Type: Shark, Shark: &SharkState{BreedTimer: 0, Energy: SharkEnergyInit}})
@@ -195,5 +237,13 @@ func StepPar(curr *World, workers int, stepSeed int64) *World {
 	}
 
 	wg.Wait()
+
+	ApplyCurrents(curr.Terrain, next.Grid, n)
+	ApplySpawn(curr.Terrain, next.Grid, n)
+
+	if ScentEnabled {
+		DiffuseScent(next)
+	}
+
 	return next
 }
@@ -1,18 +1,31 @@
 package main
 
-import "math/rand"
+import (
+	"math/rand"
+
+	"github.com/YimiaoHao/wator-project/behavior"
+)
 
 func StepSeq(w *World) *World {
 	n := w.Size
 	cur := w.Grid
 
+	if ScentEnabled {
+		DepositScent(w)
+	}
+
 	// Double buffering for next state
 	next := make([][]Cell, n)
 	for i := 0; i < n; i++ {
 		next[i] = make([]Cell, n)
 	}
 
-	//  Process Sharks First 
+	gridFn := gridKindFn(cur, n)
+	fishScentFn := scentFn(w.FishScent, n)
+	sharkScentFn := scentFn(w.SharkScent, n)
+	passableFn := terrainPassableFn(w.Terrain, n)
+
+	//  Process Sharks First
 	for y := 0; y < n; y++ {
 		for x := 0; x < n; x++ {
 			cell := cur[y][x]
@@ -28,19 +41,36 @@ func StepSeq(w *World) *World {
 			}
 			s.BreedTimer++
 
-			// Look for fish in neighboring cells
-			var fishNbrs []P
+			// Build the legal candidates for this chronon: any adjacent
+			// fish (eat), or adjacent cells empty in both 'cur' and
+			// 'next' (move, to avoid collisions).
+			var neighbors []behavior.Neighbor
 			for _, p := range neigh4(x, y, n) {
-				if cur[p.Y][p.X].Type == Fish {
-					fishNbrs = append(fishNbrs, p)
+				if w.Terrain[p.Y][p.X] == Reef {
+					continue
 				}
+				switch {
+				case cur[p.Y][p.X].Type == Fish:
+					neighbors = append(neighbors, behavior.Neighbor{Pos: toBP(p), Kind: behavior.FishCell})
+				case cur[p.Y][p.X].Type == Empty && next[p.Y][p.X].Type == Empty:
+					neighbors = append(neighbors, behavior.Neighbor{Pos: toBP(p), Kind: behavior.Empty})
+				}
+			}
+
+			ctx := behavior.BehaviorCtx{
+				Self: toBP(P{X: x, Y: y}), Size: n, Neighbors: neighbors,
+				Energy: s.Energy, BreedTimer: s.BreedTimer, BreedSteps: SharkBreedSteps,
+				Sight: Sight, ScentEnabled: ScentEnabled,
+				Grid: gridFn, Passable: passableFn, FishScent: fishScentFn, SharkScent: sharkScentFn,
+				RandFloat32: rand.Float32, RandIntn: rand.Intn,
 			}
+			action := SharkAI.Plan(ctx)
 
 			moved := false
 
-			if len(fishNbrs) > 0 {
-				// Found fish: eat it and move to that position
-				dst := fishNbrs[rand.Intn(len(fishNbrs))]
+			switch action.Kind {
+			case behavior.Eat:
+				dst := P{X: action.To.X, Y: action.To.Y}
 
 				// FIXED: Add energy gain instead of resetting to Init
 				newEnergy := s.Energy + SharkEnergyGain
@@ -50,30 +80,36 @@ func StepSeq(w *World) *World {
 					Energy:     newEnergy,
 				}}
 
-				if s.BreedTimer >= SharkBreedSteps {
-					// Breed: leave a new shark at the original position (Init energy), parent resets timer and moves
-					if next[y][x].Type == Empty {
-						next[y][x] = Cell{Type: Shark, Shark: &SharkState{BreedTimer: 0, Energy: SharkEnergyInit}}
-					}
-					mover.Shark.BreedTimer = 0
-				}
-				next[dst.Y][dst.X] = mover // Overwrite the fish (eat it)
-				moved = true
-			} else {
-				// No fish: look for empty neighbors to move into
-				var empties []P
-				for _, p := range neigh4(x, y, n) {
-					// Check if empty in current grid AND empty in next grid (to avoid collisions)
-					if cur[p.Y][p.X].Type == Empty && next[p.Y][p.X].Type == Empty {
-						empties = append(empties, p)
+				// Plan's candidates come from 'cur' (behavior.Neighbor for
+				// Eat isn't filtered through the next-empty check the
+				// Empty candidates are), so a second shark may have
+				// already claimed dst in 'next' this chronon — guard it
+				// here like every other write to 'next'.
+				if next[dst.Y][dst.X].Type == Empty {
+					if s.BreedTimer >= SharkBreedSteps {
+						// Breed: leave a new shark at the original position (Init energy), parent resets timer and moves
+						if next[y][x].Type == Empty {
+							next[y][x] = Cell{Type: Shark, Shark: &SharkState{BreedTimer: 0, Energy: SharkEnergyInit}}
+						}
+						mover.Shark.BreedTimer = 0
 					}
+					next[dst.Y][dst.X] = mover // Overwrite the fish (eat it)
+					moved = true
 				}
-				if len(empties) > 0 {
-					dst := empties[rand.Intn(len(empties))]
-					mover := Cell{Type: Shark, Shark: &SharkState{
-						BreedTimer: s.BreedTimer,
-						Energy:     s.Energy,
-					}}
+
+			case behavior.Move:
+				dst := P{X: action.To.X, Y: action.To.Y}
+				mover := Cell{Type: Shark, Shark: &SharkState{
+					BreedTimer: s.BreedTimer,
+					Energy:     s.Energy,
+				}}
+				// A* targets come from ctx.Grid (i.e. 'cur'), bypassing the
+				// pre-filtered neighbors list, so another shark may have
+				// already claimed dst in 'next' this chronon. Guard the
+				// write and fall through to the stay-in-place path below
+				// on failure, same as the double-buffer scheme everywhere
+				// else in this function.
+				if next[dst.Y][dst.X].Type == Empty {
 					if s.BreedTimer >= SharkBreedSteps {
 						// Breed
 						if next[y][x].Type == Empty {
@@ -86,6 +122,8 @@ func StepSeq(w *World) *World {
 				}
 			}
 
+			SharkAI.OnStep(ctx)
+
 			if !moved {
 				// Stay in place
 				if s.BreedTimer >= SharkBreedSteps && next[y][x].Type == Empty {
@@ -115,15 +153,27 @@ func StepSeq(w *World) *World {
 			f := *cell.Fish
 			f.BreedTimer++
 
-			var empties []P
+			var neighbors []behavior.Neighbor
 			for _, p := range neigh4(x, y, n) {
+				if w.Terrain[p.Y][p.X] == Reef {
+					continue
+				}
 				if cur[p.Y][p.X].Type == Empty && next[p.Y][p.X].Type == Empty {
-					empties = append(empties, p)
+					neighbors = append(neighbors, behavior.Neighbor{Pos: toBP(p), Kind: behavior.Empty})
 				}
 			}
 
-			if len(empties) > 0 {
-				dst := empties[rand.Intn(len(empties))]
+			ctx := behavior.BehaviorCtx{
+				Self: toBP(P{X: x, Y: y}), Size: n, Neighbors: neighbors,
+				BreedTimer: f.BreedTimer, BreedSteps: FishBreedSteps,
+				Sight: Sight, ScentEnabled: ScentEnabled,
+				Grid: gridFn, Passable: passableFn, FishScent: fishScentFn, SharkScent: sharkScentFn,
+				RandFloat32: rand.Float32, RandIntn: rand.Intn,
+			}
+			action := FishAI.Plan(ctx)
+
+			if action.Kind == behavior.Move {
+				dst := P{X: action.To.X, Y: action.To.Y}
 				mover := Cell{Type: Fish, Fish: &FishState{BreedTimer: f.BreedTimer}}
 
 				if f.BreedTimer >= FishBreedSteps {
@@ -147,10 +197,20 @@ func StepSeq(w *World) *World {
 					next[y][x] = Cell{Type: Fish, Fish: &FishState{BreedTimer: f.BreedTimer}}
 				}
 			}
+
+			FishAI.OnStep(ctx)
 		}
 	}
 
+	ApplyCurrents(w.Terrain, next, n)
+	ApplySpawn(w.Terrain, next, n)
+
 	// Commit the new generation
 	w.Grid = next
+
+	if ScentEnabled {
+		DiffuseScent(w)
+	}
+
 	return w
 }
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"os"
+)
+
+// Terrain is the fixed-for-the-run backdrop a cell sits on, independent
+// of whatever agent (if any) currently occupies it (see CellType). It
+// is kept as its own grid on World so loading/generating it never
+// touches agent placement.
+type Terrain byte
+
+const (
+	Open     Terrain = iota ///< No terrain effect.
+	Reef                    ///< Impassable: excluded from neigh4 candidates for both species.
+	CurrentN                ///< Carries its occupant one extra cell north after its normal move.
+	CurrentE                ///< ...east.
+	CurrentS                ///< ...south.
+	CurrentW                ///< ...west.
+	Spawn                   ///< Periodically materializes a new fish while left empty (see SpawnRate).
+)
+
+// SpawnRate is the probability a Spawn-terrain cell creates a new fish
+// each chronon, if the cell is currently unoccupied.
+var SpawnRate = float32(0.02)
+
+// ActiveTerrain mirrors whatever -terrain/-terrain-gen produced for this
+// invocation (nil if neither was set), set in main.go before World
+// construction. runReplayGUI applies it to the World it builds from a
+// recording when the sizes match; RunVerify ignores it and rebuilds the
+// terrain from the recording's Header instead.
+var ActiveTerrain [][]Terrain
+
+// currentDelta returns the (dx, dy) a Current terrain kind pushes an
+// occupant, or (0, 0) for any other kind.
+func currentDelta(t Terrain) (dx, dy int) {
+	switch t {
+	case CurrentN:
+		return 0, -1
+	case CurrentE:
+		return 1, 0
+	case CurrentS:
+		return 0, 1
+	case CurrentW:
+		return -1, 0
+	default:
+		return 0, 0
+	}
+}
+
+// terrainSymbols is the -terrain ASCII map alphabet: '.' Open, '#' Reef,
+// '^' '>' 'v' '<' Current (N/E/S/W), '*' Spawn.
+var terrainSymbols = map[byte]Terrain{
+	'.': Open,
+	'#': Reef,
+	'^': CurrentN,
+	'>': CurrentE,
+	'v': CurrentS,
+	'<': CurrentW,
+	'*': Spawn,
+}
+
+// LoadTerrain reads an ASCII terrain map from path (see terrainSymbols).
+// Every row must be the same length; that length becomes the returned
+// grid's side, which the caller should use as the world size.
+func LoadTerrain(path string) ([][]Terrain, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rows [][]Terrain
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			continue
+		}
+		row := make([]Terrain, len(line))
+		for i := 0; i < len(line); i++ {
+			t, ok := terrainSymbols[line[i]]
+			if !ok {
+				return nil, fmt.Errorf("terrain: unknown symbol %q at row %d col %d", line[i], len(rows), i)
+			}
+			row[i] = t
+		}
+		rows = append(rows, row)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	n := len(rows)
+	for _, row := range rows {
+		if len(row) != n {
+			return nil, fmt.Errorf("terrain: map must be square, got %d rows but a row of length %d", n, len(row))
+		}
+	}
+	return rows, nil
+}
+
+// terrainToBytes/terrainFromBytes flatten a Terrain grid to/from a
+// row-major byte slice, for embedding in a recording's Header (see
+// record.Header.TerrainGrid).
+func terrainToBytes(t [][]Terrain) []byte {
+	n := len(t)
+	b := make([]byte, 0, n*n)
+	for _, row := range t {
+		for _, cell := range row {
+			b = append(b, byte(cell))
+		}
+	}
+	return b
+}
+
+func terrainFromBytes(b []byte, n int) [][]Terrain {
+	t := make([][]Terrain, n)
+	for y := 0; y < n; y++ {
+		t[y] = make([]Terrain, n)
+		for x := 0; x < n; x++ {
+			t[y][x] = Terrain(b[y*n+x])
+		}
+	}
+	return t
+}
+
+// GenTerrain procedurally scatters reef patches over an n x n grid: each
+// cell starts as Reef with probability reefProb, then passes rounds of
+// cellular-automaton smoothing run using Moore-neighborhood (8-cell)
+// reef counts — a cell becomes/stays Reef once its neighbor count
+// reaches birth (if currently Open) or survive (if currently Reef).
+func GenTerrain(n int, reefProb float32, passes, birth, survive int) [][]Terrain {
+	grid := make([][]Terrain, n)
+	for y := range grid {
+		grid[y] = make([]Terrain, n)
+		for x := range grid[y] {
+			if rand.Float32() < reefProb {
+				grid[y][x] = Reef
+			}
+		}
+	}
+
+	for p := 0; p < passes; p++ {
+		next := make([][]Terrain, n)
+		for y := range next {
+			next[y] = make([]Terrain, n)
+		}
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				count := 0
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						if dx == 0 && dy == 0 {
+							continue
+						}
+						if grid[wrap(y+dy, n)][wrap(x+dx, n)] == Reef {
+							count++
+						}
+					}
+				}
+				threshold := birth
+				if grid[y][x] == Reef {
+					threshold = survive
+				}
+				if count >= threshold {
+					next[y][x] = Reef
+				}
+			}
+		}
+		grid = next
+	}
+	return grid
+}
+
+// ApplyCurrents sweeps next for occupants sitting on Current terrain and
+// pushes each one more cell in the stored direction, landing only if
+// the destination is both unoccupied and not reef. It runs once,
+// sequentially, after every agent's normal move has already been
+// committed to next — by StepSeq directly, and by StepPar only after
+// its goroutines finish — so it never races with the concurrent writes
+// that built next, without needing any extra row locks of its own.
+func ApplyCurrents(terrain [][]Terrain, next [][]Cell, n int) {
+	// Source cells are read from a snapshot taken before any pushes, so
+	// an occupant carried into a cell mid-pass is never read back out of
+	// it and pushed again: a row-major scan would otherwise chain an
+	// occupant many cells down a run of same-direction currents (and do
+	// so asymmetrically, since only directions the scan revisits after
+	// writing can chain). Destination occupancy still checks the live
+	// 'next' buffer, so two currents landing on the same cell in one
+	// pass still resolve first-write-wins instead of clobbering.
+	orig := make([][]Cell, n)
+	for y := 0; y < n; y++ {
+		orig[y] = make([]Cell, n)
+		copy(orig[y], next[y])
+	}
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			dx, dy := currentDelta(terrain[y][x])
+			if dx == 0 && dy == 0 {
+				continue
+			}
+			if orig[y][x].Type == Empty {
+				continue
+			}
+			tx, ty := wrap(x+dx, n), wrap(y+dy, n)
+			if terrain[ty][tx] == Reef || next[ty][tx].Type != Empty {
+				continue
+			}
+			next[ty][tx] = orig[y][x]
+			next[y][x] = Cell{}
+		}
+	}
+}
+
+// ApplySpawn materializes a new fish at each empty Spawn-terrain cell
+// with probability SpawnRate, keeping long-running simulations from
+// fishing a region out permanently.
+func ApplySpawn(terrain [][]Terrain, next [][]Cell, n int) {
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			if terrain[y][x] != Spawn || next[y][x].Type != Empty {
+				continue
+			}
+			if rand.Float32() < SpawnRate {
+				next[y][x] = Cell{Type: Fish, Fish: &FishState{}}
+			}
+		}
+	}
+}
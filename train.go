@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/YimiaoHao/wator-project/behavior"
+	"github.com/YimiaoHao/wator-project/policy"
+)
+
+// Reward shaping for RunTrain: +1 per fish eaten, -1 on starvation, and
+// a small per-chronon penalty so the policy doesn't learn to idle.
+const (
+	rewardEat       = float32(1)
+	rewardStarve    = float32(-1)
+	rewardPerChrono = float32(-0.01)
+	discount        = float32(0.95)
+	trainLR         = float32(0.01)
+)
+
+// rawStep is one recorded (state, action, immediate reward) sample from
+// a training episode, before it is turned into a policy.Step with a
+// discounted return.
+type rawStep struct {
+	input  []float32
+	action int
+	reward float32
+}
+
+// recordingPolicy is installed as SharkAI during training: it samples
+// an action from net the same way policy.SharkPolicy's Argmax does
+// greedily, except stochastically, and remembers the (input, action)
+// pair so RunTrain can score it once the chronon's outcome is known.
+type recordingPolicy struct {
+	net     *policy.Net
+	workers int
+	input   []float32
+	action  int
+}
+
+func (r *recordingPolicy) Plan(ctx behavior.BehaviorCtx) behavior.Action {
+	r.input = policy.Encode(ctx)
+	logits := r.net.Forward(r.input, r.workers)
+	r.action = policy.Sample(policy.Softmax(logits), ctx.RandFloat32)
+	return policy.ActionFromIndex(ctx, r.action)
+}
+
+func (r *recordingPolicy) OnStep(ctx behavior.BehaviorCtx) {}
+
+// RunTrain trains a shark policy network by self-play: each episode
+// seeds a fresh world with exactly one learner shark among numFish
+// fish, rolls it forward for up to chronons chronons (ending early if
+// the shark starves), and applies a REINFORCE update from the episode's
+// trajectory. The trained weights are written to weightsPath.
+func RunTrain(size, numFish, episodes, chronons, workers int, weightsPath string) {
+	net := policy.NewNet(policy.Window)
+	rp := &recordingPolicy{net: net, workers: workers}
+
+	origSharkAI, origFishAI := SharkAI, FishAI
+	origBreedSteps := SharkBreedSteps
+	SharkAI = rp
+	FishAI = behavior.NewFish("random")
+	// Breeding would give the episode a second shark to track; since
+	// RunTrain only ever looks for a single learner cell, breeding is
+	// disabled for the duration of training by pushing the threshold
+	// out past any episode's length.
+	SharkBreedSteps = chronons + 1
+	defer func() {
+		SharkAI, FishAI = origSharkAI, origFishAI
+		SharkBreedSteps = origBreedSteps
+	}()
+
+	for ep := 0; ep < episodes; ep++ {
+		w := NewWorld(size)
+		SeedRandom(w, numFish, 1)
+
+		var trajectory []rawStep
+		for c := 0; c < chronons; c++ {
+			beforeEnergy, alive := findSharkEnergy(w)
+			if !alive {
+				break
+			}
+
+			w = StepSeq(w)
+
+			afterEnergy, stillAlive := findSharkEnergy(w)
+			reward := rewardPerChrono
+			switch {
+			case !stillAlive:
+				reward = rewardStarve
+			case afterEnergy > beforeEnergy:
+				reward = rewardEat
+			}
+			trajectory = append(trajectory, rawStep{input: rp.input, action: rp.action, reward: reward})
+
+			if !stillAlive {
+				break
+			}
+		}
+
+		net.Update(discountedReturns(trajectory), trainLR, workers)
+
+		if ep%10 == 0 {
+			fmt.Printf("train episode=%d steps=%d\n", ep, len(trajectory))
+		}
+	}
+
+	f, err := os.Create(weightsPath)
+	if err != nil {
+		log.Fatalf("-train: could not create %s: %v", weightsPath, err)
+	}
+	defer f.Close()
+	if err := net.Save(f); err != nil {
+		log.Fatalf("-train: could not write weights: %v", err)
+	}
+}
+
+// findSharkEnergy scans w for the (single) surviving shark, returning
+// its energy and whether one was found.
+func findSharkEnergy(w *World) (energy int, ok bool) {
+	for y := 0; y < w.Size; y++ {
+		for x := 0; x < w.Size; x++ {
+			if c := w.Grid[y][x]; c.Type == Shark {
+				return c.Shark.Energy, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// discountedReturns converts a trajectory's immediate rewards into
+// policy.Steps carrying the discounted return from each step onward.
+func discountedReturns(traj []rawStep) []policy.Step {
+	steps := make([]policy.Step, len(traj))
+	var g float32
+	for i := len(traj) - 1; i >= 0; i-- {
+		g = traj[i].reward + discount*g
+		steps[i] = policy.Step{Input: traj[i].input, Action: traj[i].action, Return: g}
+	}
+	return steps
+}
@@ -1,11 +1,17 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"image/color"
+	"io"
+	"os"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"github.com/YimiaoHao/wator-project/record"
 )
 
 const pixelScale = 5 // Pixels per cell, increase for better visibility
@@ -14,18 +20,25 @@ var (
 	colBg    = color.RGBA{20, 40, 90, 255}    // Ocean color (Background)
 	colFish  = color.RGBA{255, 230, 120, 255} // Fish color: Yellow
 	colShark = color.RGBA{220, 60, 60, 255}   // Shark color: Red
-)
 
+	colReef    = color.RGBA{90, 70, 50, 255}   // Reef terrain: brown
+	colCurrent = color.RGBA{40, 120, 170, 255} // Current terrain: lighter blue
+	colSpawn   = color.RGBA{40, 140, 70, 255}  // Spawn terrain: green
+)
 
 type game struct {
-	w       *World // Pointer to the simulation world
-	mode    string // Execution mode: "seq" or "par"
-	workers int    // Number of threads for parallel mode
-	tick    int    // Frame counter to control simulation speed
+	w         *World // Pointer to the simulation world
+	mode      string // Execution mode: "seq" or "par"
+	workers   int    // Number of threads for parallel mode
+	tick      int    // Frame counter to control simulation speed
+	showScent bool   // Debug overlay: blend FishScent/SharkScent over the grid
 }
 
-
 func (g *game) Update() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		g.showScent = !g.showScent
+	}
+
 	// Advance one simulation step every two frames
 	if g.tick%2 != 0 {
 		g.tick++
@@ -49,6 +62,10 @@ func (g *game) Draw(screen *ebiten.Image) {
 	n := g.w.Size
 	for y := 0; y < n; y++ {
 		for x := 0; x < n; x++ {
+			drawTerrainCell(screen, g.w, x, y)
+			if g.showScent {
+				drawScentCell(screen, g.w, x, y)
+			}
 			var c color.Color
 			switch g.w.Grid[y][x].Type {
 			case Fish:
@@ -68,12 +85,74 @@ func (g *game) Draw(screen *ebiten.Image) {
 	}
 }
 
+// drawTerrainCell paints the Terrain layer beneath the scent overlay and
+// agents; Open cells are left as the plain ocean background.
+func drawTerrainCell(screen *ebiten.Image, w *World, x, y int) {
+	var c color.RGBA
+	switch w.Terrain[y][x] {
+	case Reef:
+		c = colReef
+	case CurrentN, CurrentE, CurrentS, CurrentW:
+		c = colCurrent
+	case Spawn:
+		c = colSpawn
+	default:
+		return
+	}
+	for dy := 0; dy < pixelScale; dy++ {
+		for dx := 0; dx < pixelScale; dx++ {
+			screen.Set(x*pixelScale+dx, y*pixelScale+dy, c)
+		}
+	}
+}
+
+// drawScentCell blends FishScent (green) and SharkScent (red) for cell
+// (x, y) over the background color, so trails show through as a
+// translucent tint beneath the agent layer. Toggled in-game with the S key.
+func drawScentCell(screen *ebiten.Image, w *World, x, y int) {
+	clamp01 := func(v float32) float32 {
+		if v < 0 {
+			return 0
+		}
+		if v > 1 {
+			return 1
+		}
+		return v
+	}
+	fa := clamp01(w.FishScent[y][x])
+	sa := clamp01(w.SharkScent[y][x])
+	alpha := fa
+	if sa > alpha {
+		alpha = sa
+	}
+	if alpha == 0 {
+		return
+	}
+	tint := color.RGBA{uint8(sa * 255), uint8(fa * 255), 0, 255}
+	blend := blendOver(colBg, tint, alpha*0.6)
+	for dy := 0; dy < pixelScale; dy++ {
+		for dx := 0; dx < pixelScale; dx++ {
+			screen.Set(x*pixelScale+dx, y*pixelScale+dy, blend)
+		}
+	}
+}
+
+// blendOver alpha-composites tint over bg (screen.Set overwrites pixels
+// rather than compositing, so the blend has to be done here).
+func blendOver(bg, tint color.RGBA, alpha float32) color.RGBA {
+	inv := 1 - alpha
+	return color.RGBA{
+		R: uint8(float32(bg.R)*inv + float32(tint.R)*alpha),
+		G: uint8(float32(bg.G)*inv + float32(tint.G)*alpha),
+		B: uint8(float32(bg.B)*inv + float32(tint.B)*alpha),
+		A: 255,
+	}
+}
 
 func (g *game) Layout(outW, outH int) (int, int) {
 	return g.w.Size * pixelScale, g.w.Size * pixelScale
 }
 
-
 func runGUI(w *World, mode string, workers int) error {
 	g := &game{w: w, mode: mode, workers: workers}
 	f0, s0 := Count(w)
@@ -84,3 +163,88 @@ func runGUI(w *World, mode string, workers int) error {
 	))
 	return ebiten.RunGame(g)
 }
+
+// errReplayDone is returned by replayGame.Update once the recording is
+// exhausted, so runReplayGUI can tell a clean end-of-stream apart from a
+// real playback error.
+var errReplayDone = errors.New("record: end of recording")
+
+// replayGame implements ebiten.Game by pulling frames from a record.Reader
+// instead of stepping the simulation, so -replay can reuse the same
+// renderer as a live run.
+type replayGame struct {
+	w      *World
+	reader *record.Reader
+}
+
+func (g *replayGame) Update() error {
+	cells, err := g.reader.ReadFrame()
+	if err == io.EOF {
+		return errReplayDone
+	}
+	if err != nil {
+		return err
+	}
+	applyCells(g.w, cells)
+	return nil
+}
+
+func (g *replayGame) Draw(screen *ebiten.Image) {
+	screen.Fill(colBg)
+	n := g.w.Size
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			drawTerrainCell(screen, g.w, x, y)
+			var c color.Color
+			switch g.w.Grid[y][x].Type {
+			case Fish:
+				c = colFish
+			case Shark:
+				c = colShark
+			default:
+				continue
+			}
+			for dy := 0; dy < pixelScale; dy++ {
+				for dx := 0; dx < pixelScale; dx++ {
+					screen.Set(x*pixelScale+dx, y*pixelScale+dy, c)
+				}
+			}
+		}
+	}
+}
+
+func (g *replayGame) Layout(outW, outH int) (int, int) {
+	return g.w.Size * pixelScale, g.w.Size * pixelScale
+}
+
+// runReplayGUI opens a recording and plays it back at fps frames per
+// second instead of running the simulation.
+func runReplayGUI(path string, fps int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rd, h, err := record.NewReader(f)
+	if err != nil {
+		return err
+	}
+
+	w := NewWorld(int(h.Size))
+	if ActiveTerrain != nil && len(ActiveTerrain) == int(h.Size) {
+		w.Terrain = ActiveTerrain
+	}
+	g := &replayGame{w: w, reader: rd}
+	ebiten.SetTPS(fps)
+	ebiten.SetWindowSize(g.w.Size*pixelScale, g.w.Size*pixelScale)
+	ebiten.SetWindowTitle(fmt.Sprintf(
+		"Wa-Tor replay | size=%d mode=%s workers=%d seed=%d",
+		h.Size, h.Mode, h.Workers, h.Seed,
+	))
+
+	if err := ebiten.RunGame(g); err != nil && !errors.Is(err, errReplayDone) {
+		return err
+	}
+	return nil
+}
@@ -22,6 +22,11 @@ var (
 	SharkEnergyInit = 5 ///< Initial energy of a shark (and energy gained from eating).
 	SharkEnergyGain = 2 ///< Energy gained by a shark when eating a fish.
 	SharkEnergyLoss = 1 ///< Energy lost by a shark each chronon.
+
+	ScentEnabled = false         ///< Enables the pheromone scent-trail subsystem (see -scent flag).
+	ScentDeposit = float32(1)    ///< Scent added to an agent's cell each chronon, before movement.
+	ScentDecay   = float32(0.90) ///< Multiplicative per-chronon decay applied after diffusion.
+	ScentDiffuse = float32(0.15) ///< Fraction of a cell's scent spread to each of its 4 neighbors.
 )
 
 type FishState struct {
@@ -39,10 +44,12 @@ type Cell struct {
 	Shark *SharkState ///< Pointer to shark state (nil if Type != Shark).
 }
 
-
 type World struct {
-	Size int      ///< The width and height of the square grid.
-	Grid [][]Cell ///< 2D matrix representing the world state.
+	Size       int         ///< The width and height of the square grid.
+	Grid       [][]Cell    ///< 2D matrix representing the world state.
+	FishScent  [][]float32 ///< Pheromone trail left by fish, used by sharks to hunt.
+	SharkScent [][]float32 ///< Pheromone trail left by sharks, used by fish to flee.
+	Terrain    [][]Terrain ///< Fixed-for-the-run backdrop (see terrain.go); all Open by default.
 }
 
 type P struct {
@@ -54,23 +61,32 @@ type P struct {
 
 func NewWorld(size int) *World {
 	g := make([][]Cell, size)
+	fishScent := make([][]float32, size)
+	sharkScent := make([][]float32, size)
+	terrain := make([][]Terrain, size)
 	for i := range g {
 		g[i] = make([]Cell, size)
+		fishScent[i] = make([]float32, size)
+		sharkScent[i] = make([]float32, size)
+		terrain[i] = make([]Terrain, size) // zero value Open
 	}
-	return &World{Size: size, Grid: g}
+	return &World{Size: size, Grid: g, FishScent: fishScent, SharkScent: sharkScent, Terrain: terrain}
 }
 
-
 func init() { rand.Seed(time.Now().UnixNano()) }
 
 func SeedRandom(w *World, numFish, numShark int) {
 	total := w.Size * w.Size
-	idx := make([]int, total)
-	// Create a list of all possible indices 0 to total-1
+	idx := make([]int, 0, total)
+	// Build the list of placeable indices, skipping reef (impassable terrain)
 	for i := 0; i < total; i++ {
-		idx[i] = i
+		x, y := i%w.Size, i/w.Size
+		if w.Terrain[y][x] != Reef {
+			idx = append(idx, i)
+		}
 	}
 	// Shuffle the indices
+	total = len(idx)
 	rand.Shuffle(total, func(i, j int) { idx[i], idx[j] = idx[j], idx[i] })
 
 	pos := 0
@@ -100,6 +116,60 @@ func SeedRandom(w *World, numFish, numShark int) {
 	}
 }
 
+// Scent Trail Subsystem
+
+// DepositScent adds ScentDeposit into the scent grid matching each
+// occupied cell's type. Called once per chronon, before movement, so the
+// trail reflects agent positions at the start of the step.
+func DepositScent(w *World) {
+	for y := 0; y < w.Size; y++ {
+		for x := 0; x < w.Size; x++ {
+			switch w.Grid[y][x].Type {
+			case Fish:
+				w.FishScent[y][x] += ScentDeposit
+			case Shark:
+				w.SharkScent[y][x] += ScentDeposit
+			}
+		}
+	}
+}
+
+// DiffuseScent spreads FishScent and SharkScent across neigh4
+// neighbors (a 5-point stencil) and applies ScentDecay. Each grid's
+// result is computed into a scratch buffer and only swapped in once that
+// grid is fully updated, so the scan order does not bias the spread
+// direction.
+func DiffuseScent(w *World) {
+	n := w.Size
+	spread := func(src [][]float32) [][]float32 {
+		out := make([][]float32, n)
+		for i := range out {
+			out[i] = make([]float32, n)
+		}
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				v := src[y][x]
+				if v == 0 {
+					continue
+				}
+				leak := v * ScentDiffuse
+				out[y][x] += v - 4*leak
+				for _, p := range neigh4(x, y, n) {
+					out[p.Y][p.X] += leak
+				}
+			}
+		}
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				out[y][x] *= ScentDecay
+			}
+		}
+		return out
+	}
+	w.FishScent = spread(w.FishScent)
+	w.SharkScent = spread(w.SharkScent)
+}
+
 // Helper Functions (Geometry & Stats)
 
 func wrap(i, n int) int {
@@ -121,7 +191,6 @@ func neigh4(x, y, n int) [4]P {
 	}
 }
 
-
 func Count(w *World) (fish, sharks int) {
 	for y := 0; y < w.Size; y++ {
 		for x := 0; x < w.Size; x++ {
@@ -136,7 +205,6 @@ func Count(w *World) (fish, sharks int) {
 	return
 }
 
-
 func PrintWorld(w *World, max int) {
 	n := w.Size
 	if n > max {